@@ -2,224 +2,214 @@
 package go_rds_iam
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
-	"fmt"
 	"log"
-	"regexp"
-	"strconv"
-	"strings"
-	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
 	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
-)
 
-var (
-	// mysqlRegex is a regular expression used to parse MySQL connection strings.
-	mysqlRegex *regexp.Regexp
+	"github.com/RohanPoojary/go-rds-iam/internal/rdsdsn"
 )
 
-func init() {
-	mysqlRegex = regexp.MustCompile(`^(?P<user>[^:]+):(?P<password>[^@]+)@tcp\((?P<host>[^:]+):(?P<port>\d+)\)\/(?P<dbname>[^?]+)`)
-}
+// defaultTokenTTL is how long a cached IAM auth token is considered fresh.
+// RDS IAM tokens expire 15 minutes after they're minted; refreshing a few
+// minutes early avoids handing out a token that's about to be rejected.
+const defaultTokenTTL = 10 * time.Minute
 
-// ConnectionRequest represents a request to connect to an RDS instance.
-type ConnectionRequest struct {
-	RDSType ConnectionRDSType
-	Region  string
-
-	DBUser             string
-	Hostname           string
-	Port               int
-	DBName             string
-	SSLMode            string
-	SSLCertificatePath string
-}
+// defaultPostgresDriver is used when WithPostgresDriver isn't given.
+const defaultPostgresDriver = "pq"
 
-func (cr *ConnectionRequest) sanitize() {
-	if cr.RDSType == "" {
-		cr.RDSType = "postgres"
-	}
+// defaultResolutionTTL is how long a Resolver's answer is cached before
+// being treated as stale. It's kept short relative to defaultTokenTTL so a
+// failover is picked up quickly rather than pinning connections to a
+// now-wrong endpoint for minutes.
+const defaultResolutionTTL = 30 * time.Second
 
-	if cr.Region == "" {
-		cr.Region = "ap-south-1"
-	}
-
-	if cr.DBUser == "" {
-		cr.DBUser = "postgres"
-	}
+// Option configures a GenericIAMDriver constructed by RegisterAWSRDSIAMDrivers
+// or NewConnector.
+type Option func(*GenericIAMDriver)
 
-	if cr.Hostname == "" {
-		cr.Hostname = "localhost"
+// WithTokenTTL overrides how long a cached IAM auth token is considered
+// fresh. It should stay comfortably under RDS's 15-minute token expiry.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(d *GenericIAMDriver) {
+		d.tokenTTL = ttl
 	}
+}
 
-	if cr.Port == 0 {
-		if cr.RDSType == "postgres" {
-			cr.Port = 5432
-		} else if cr.RDSType == "mysql" {
-			cr.Port = 3306
-		}
+// WithPostgresDriver selects which driver backs postgres connections: "pq"
+// (the default, github.com/lib/pq) or "pgx" (github.com/jackc/pgx/v5/stdlib).
+// It has no effect for mysql requests.
+func WithPostgresDriver(name string) Option {
+	return func(d *GenericIAMDriver) {
+		d.postgresDriver = name
 	}
+}
 
-	if cr.DBName == "" {
-		cr.DBName = "postgres"
+// WithResolver has the driver resolve a ConnectionRequest's Hostname through
+// r (e.g. a SRVResolver or ClusterResolver) before minting an IAM auth token,
+// so connections follow Aurora/Multi-AZ failovers instead of targeting a
+// single static endpoint.
+func WithResolver(r rdsdsn.Resolver) Option {
+	return func(d *GenericIAMDriver) {
+		d.resolver = r
 	}
 }
 
-func createRDSConnectionString(sess *session.Session, request ConnectionRequest) (string, error) {
-	creds := sess.Config.Credentials
-
-	req := request
-	req.sanitize()
+// ConnectionRDSType identifies which RDS engine a ConnectionRequest targets.
+type ConnectionRDSType = rdsdsn.ConnectionRDSType
 
-	dbEndpoint := fmt.Sprintf("%s:%d", req.Hostname, req.Port)
-	authToken, err := rdsutils.BuildAuthToken(dbEndpoint, req.Region, req.DBUser, creds)
-	if err != nil {
-		return "", errors.Wrap(err, "Unable to generate RDS auth token")
-	}
-
-	switch req.RDSType {
-	case "postgres":
-		connectionString := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s",
-			req.Hostname, req.Port, req.DBUser, authToken, req.DBName,
-		)
+// ConnectionRequest represents a request to connect to an RDS instance.
+type ConnectionRequest = rdsdsn.ConnectionRequest
+
+// Certificates is meant to be Amazon's RDS global CA bundle (which also
+// covers RDS Proxy endpoints) in PEM format. It's used automatically when a
+// ConnectionRequest leaves SSLCertificatePath empty and sets SSLMode to a
+// value other than "disable" (postgres) or to "rds" (mysql); see
+// rdsdsn.Certificates for the current placeholder caveat.
+var Certificates = rdsdsn.Certificates
+
+// sessionTokenBuilder generates IAM auth tokens via aws-sdk-go (v1), so that
+// createRDSConnectionString can share its DSN building logic with the
+// aws-sdk-go-v2 backend in the sibling rdsiamv2 package.
+type sessionTokenBuilder struct {
+	sess *session.Session
+}
 
-		if req.SSLMode != "" {
-			connectionString += fmt.Sprintf(" sslmode=%s", req.SSLMode)
-		}
+func (b *sessionTokenBuilder) BuildAuthToken(_ context.Context, endpoint, region, user string) (string, error) {
+	return rdsutils.BuildAuthToken(endpoint, region, user, b.sess.Config.Credentials)
+}
 
-		if req.SSLCertificatePath != "" {
-			connectionString += fmt.Sprintf(" sslrootcert=%s", req.SSLCertificatePath)
-		}
+func createRDSConnectionString(sess *session.Session, request ConnectionRequest, pgFormat rdsdsn.PostgresDSNFormat) (string, error) {
+	return rdsdsn.CreateRDSConnectionString(context.Background(), &sessionTokenBuilder{sess: sess}, request, pgFormat)
+}
 
-		return connectionString, nil
-	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s)/%s?allowCleartextPasswords=true",
-			req.DBUser, authToken, dbEndpoint, req.DBName,
-		), nil
-	default:
-		return "", errors.Errorf("Invalid RDSType: %v, Only 'postgres' or 'mysql' is supported", req.RDSType)
-	}
+// GenericIAMDriver is a database driver that uses IAM authentication to connect to RDS instances.
+type GenericIAMDriver struct {
+	awsSession     *session.Session
+	rdsType        ConnectionRDSType
+	tokenTTL       time.Duration
+	postgresDriver string
+	resolver       rdsdsn.Resolver
+	tokens         *rdsdsn.TokenCache
+	resolutions    *rdsdsn.ResolutionCache
 }
 
-func getPostgresValues(dsn string) map[string]string {
-	if dsn == "" {
-		return nil
+// postgresDSNFormat reports which DSN shape to build for the configured
+// postgres driver.
+func (d *GenericIAMDriver) postgresDSNFormat() rdsdsn.PostgresDSNFormat {
+	if d.postgresDriver == "pgx" {
+		return rdsdsn.PostgresDSNURL
 	}
 
-	result := make(map[string]string)
-	dsnSplits := strings.Split(dsn, " ")
-	for _, dsnSplit := range dsnSplits {
-		valueSplit := strings.SplitN(dsnSplit, "=", 2)
-		if len(valueSplit) != 2 {
-			continue
-		}
+	return rdsdsn.PostgresDSNKeyword
+}
 
-		result[valueSplit[0]] = valueSplit[1]
+// Open opens a new database connection using IAM authentication.
+func (d *GenericIAMDriver) Open(dsn string) (driver.Conn, error) {
+	request, err := rdsdsn.ParseConnectionRequestFromDSN(d.rdsType, dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error in Parsing DSN: "+dsn)
 	}
 
-	return result
-}
+	request.Region = *d.awsSession.Config.Region
+	request.RDSType = ConnectionRDSType(d.rdsType)
 
-func getMysqlValues(input string) map[string]string {
-	if input == "" {
-		return nil
-	}
+	return d.openCached(dsn, request)
+}
 
-	// Extract named capture groups from the input string
-	match := mysqlRegex.FindStringSubmatch(input)
-	// Extract values from named capture groups
-	result := make(map[string]string)
-	for i, name := range mysqlRegex.SubexpNames() {
-		if i != 0 && name != "" {
-			result[name] = match[i]
+// openCached opens a connection for request, reusing the IAM DSN cached under
+// cacheKey while it's still within the driver's token TTL, and only
+// regenerating it when the cache misses, the entry is stale, or the cached
+// connection fails to open. It is shared by Open, which keys the cache by the
+// raw input DSN, and connector.Connect, which has no DSN string to key by.
+func (d *GenericIAMDriver) openCached(cacheKey string, request ConnectionRequest) (driver.Conn, error) {
+	if iamDSN, ok := d.tokens.Get(cacheKey); ok {
+		if conn, err := d.open(iamDSN); err == nil {
+			log.Println("Using cached IAM DSN for connection: ", cacheKey)
+			return conn, nil
 		}
 	}
 
-	return result
-}
-
-func parseConnectionRequestFromDSN(rdsType ConnectionRDSType, dsn string) (ConnectionRequest, error) {
-	var matchValues map[string]string
+	iamDSN, err := d.tokens.Refresh(cacheKey, func() (string, error) {
+		resolved, err := d.resolveRequest(request)
+		if err != nil {
+			return "", errors.Wrap(err, "resolving RDS endpoint")
+		}
 
-	if rdsType == "postgres" {
-		matchValues = getPostgresValues(dsn)
-	} else if rdsType == "mysql" {
-		matchValues = getMysqlValues(dsn)
-	} else {
-		return ConnectionRequest{}, errors.Errorf("Invalid RDSType: %v, Only 'postgres' or 'mysql' is supported", rdsType)
+		return createRDSConnectionString(d.awsSession, resolved, d.postgresDSNFormat())
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "createRDSConnectionString")
 	}
 
-	if len(matchValues) == 0 {
-		return ConnectionRequest{}, errors.Errorf("Invalid DSN: %v", dsn)
-	}
+	log.Println("Created New DSN for connection: ", cacheKey)
 
-	portString := matchValues["port"]
-	port, _ := strconv.Atoi(portString)
-
-	return ConnectionRequest{
-		RDSType:            rdsType,
-		DBUser:             matchValues["user"],
-		Hostname:           matchValues["host"],
-		Port:               port,
-		DBName:             matchValues["dbname"],
-		SSLMode:            matchValues["sslmode"],
-		SSLCertificatePath: matchValues["sslrootcert"],
-	}, nil
-}
+	conn, err := d.open(iamDSN)
+	if err != nil && d.resolver != nil {
+		// The resolved endpoint may no longer be valid (e.g. a failover just
+		// happened); drop it so the next attempt re-resolves instead of
+		// repeating the same bad target until the TTL lapses.
+		cacheKey := request.Hostname
+		if request.ReadOnly {
+			if _, ok := d.resolver.(rdsdsn.ReadOnlyResolver); ok {
+				cacheKey += "|reader"
+			}
+		}
+		d.resolutions.Invalidate(cacheKey)
+	}
 
-// GenericIAMDriver is a database driver that uses IAM authentication to connect to RDS instances.
-type GenericIAMDriver struct {
-	awsSession  *session.Session
-	rdsType     ConnectionRDSType
-	cachedCreds sync.Map
+	return conn, err
 }
 
-// Open opens a new database connection using IAM authentication.
-func (d *GenericIAMDriver) Open(dsn string) (driver.Conn, error) {
+// resolveRequest replaces request.Hostname/Port with the result of resolving
+// request.Hostname through d.resolver, if one is configured. When the
+// request has ReadOnly set and d.resolver implements rdsdsn.ReadOnlyResolver,
+// its ResolveReadOnly method is consulted instead of Resolve. It's a no-op
+// when no resolver is set, so Hostname keeps being treated as a static
+// endpoint the way it always has.
+func (d *GenericIAMDriver) resolveRequest(request ConnectionRequest) (ConnectionRequest, error) {
+	if d.resolver == nil {
+		return request, nil
+	}
 
-	var iamDSN string
-	var err error
+	resolve := d.resolver.Resolve
+	cacheKey := request.Hostname
 
-	// Try making a connection with the cached IAM DSN
-	if creds, ok := d.cachedCreds.Load(dsn); ok {
-		iamDSN = creds.(string)
-		if conn, err := d.open(iamDSN); err == nil {
-			log.Println("Using cached IAM DSN for connection: ", dsn)
-			return conn, nil
+	if request.ReadOnly {
+		if ro, ok := d.resolver.(rdsdsn.ReadOnlyResolver); ok {
+			resolve = ro.ResolveReadOnly
+			cacheKey += "|reader"
 		}
 	}
 
-	iamDSN, err = d.generateNewIAMDSN(dsn)
+	host, port, err := d.resolutions.Resolve(cacheKey, func() (string, int, error) {
+		return resolve(context.Background(), request.Hostname)
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "generateNewIAMDSN")
+		return ConnectionRequest{}, err
 	}
 
-	d.cachedCreds.Store(dsn, iamDSN)
-	log.Println("Created New DSN for connection: ", dsn)
-
-	return d.open(iamDSN)
-}
-
-func (d *GenericIAMDriver) generateNewIAMDSN(dsn string) (string, error) {
-	request, err := parseConnectionRequestFromDSN(d.rdsType, dsn)
-	if err != nil {
-		return "", errors.Wrap(err, "Error in Parsing DSN: "+dsn)
+	request.Hostname = host
+	if port != 0 {
+		request.Port = port
 	}
 
-	request.Region = *d.awsSession.Config.Region
-	request.RDSType = ConnectionRDSType(d.rdsType)
-
-	iamDSN, err := createRDSConnectionString(d.awsSession, request)
-	return iamDSN, errors.Wrap(err, "createRDSConnectionString")
+	return request, nil
 }
 
 func (d *GenericIAMDriver) open(dsn string) (driver.Conn, error) {
 	if d.rdsType == "postgres" {
+		if d.postgresDriver == "pgx" {
+			return stdlib.GetDefaultDriver().Open(dsn)
+		}
 		return pq.Driver{}.Open(dsn)
 	} else {
 		return mysql.MySQLDriver{}.Open(dsn)
@@ -228,13 +218,27 @@ func (d *GenericIAMDriver) open(dsn string) (driver.Conn, error) {
 
 // RegisterAWSRDSIAMDrivers registers a new database driver for the given RDS type (e.g., "postgres" or "mysql")
 // that uses IAM authentication to connect to RDS instances.
-func RegisterAWSRDSIAMDrivers(sess *session.Session, rdsType ConnectionRDSType) string {
+func RegisterAWSRDSIAMDrivers(sess *session.Session, rdsType ConnectionRDSType, opts ...Option) string {
 	driverName := "aws_" + string(rdsType) + "_iam"
-	sql.Register(driverName, &GenericIAMDriver{
-		awsSession:  sess,
-		rdsType:     rdsType,
-		cachedCreds: sync.Map{},
-	})
+	sql.Register(driverName, newGenericIAMDriver(sess, rdsType, opts...))
 
 	return driverName
 }
+
+func newGenericIAMDriver(sess *session.Session, rdsType ConnectionRDSType, opts ...Option) *GenericIAMDriver {
+	d := &GenericIAMDriver{
+		awsSession:     sess,
+		rdsType:        rdsType,
+		tokenTTL:       defaultTokenTTL,
+		postgresDriver: defaultPostgresDriver,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d.tokens = rdsdsn.NewTokenCache(d.tokenTTL)
+	d.resolutions = rdsdsn.NewResolutionCache(defaultResolutionTTL)
+
+	return d
+}
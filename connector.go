@@ -0,0 +1,91 @@
+package go_rds_iam
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+var (
+	_ driver.Connector = (*connector)(nil)
+	_ io.Closer        = (*connector)(nil)
+)
+
+// connector implements driver.Connector for a single ConnectionRequest,
+// letting callers skip the DSN string that GenericIAMDriver.Open otherwise
+// has to parse back out with a regex.
+type connector struct {
+	driver   *GenericIAMDriver
+	request  ConnectionRequest
+	cacheKey string
+}
+
+// NewConnector returns a driver.Connector wired to req, so callers can do
+// sql.OpenDB(connector) directly instead of going through
+// RegisterAWSRDSIAMDrivers and a hand-built DSN string.
+func NewConnector(sess *session.Session, req ConnectionRequest, opts ...Option) (driver.Connector, error) {
+	request := req
+	request.Sanitize()
+	request.Region = *sess.Config.Region
+
+	return &connector{
+		driver:   newGenericIAMDriver(sess, request.RDSType, opts...),
+		request:  request,
+		cacheKey: fmt.Sprintf("%s:%d/%s?user=%s", request.Hostname, request.Port, request.DBName, request.DBUser),
+	}, nil
+}
+
+// Connect generates (or reuses a cached) IAM auth token and opens the
+// underlying pq/mysql connection. openCached itself isn't context-aware (the
+// pq and mysql drivers it dials through don't take one either), so Connect
+// can't abort the in-flight token generation or dial when ctx is canceled -
+// it only stops waiting on it; see connectWithContext.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return connectWithContext(ctx, func() (driver.Conn, error) {
+		return c.driver.openCached(c.cacheKey, c.request)
+	})
+}
+
+// connectWithContext races ctx against open, which is assumed to not be
+// context-aware itself. If ctx is canceled first, Connect returns ctx.Err()
+// without waiting for open, but the goroutine running it keeps going in the
+// background; if it later succeeds anyway, the resulting connection is
+// closed instead of leaked, since nothing else will ever read it.
+func connectWithContext(ctx context.Context, open func() (driver.Conn, error)) (driver.Conn, error) {
+	type result struct {
+		conn driver.Conn
+		err  error
+	}
+
+	resultCh := make(chan result, 1)
+	go func() {
+		conn, err := open()
+		resultCh <- result{conn, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		go func() {
+			if r := <-resultCh; r.err == nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	case r := <-resultCh:
+		return r.conn, r.err
+	}
+}
+
+// Driver returns the underlying driver.Driver, as required by driver.Connector.
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close is a no-op; it exists so connector satisfies io.Closer for callers
+// that defer Close() symmetrically with sql.OpenDB.
+func (c *connector) Close() error {
+	return nil
+}
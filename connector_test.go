@@ -0,0 +1,70 @@
+package go_rds_iam
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeConn is a minimal driver.Conn that records whether Close was called.
+type fakeConn struct {
+	closed atomic.Bool
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (c *fakeConn) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not implemented") }
+
+// TestConnectWithContext_ClosesConnectionOpenedAfterCancel guards against the
+// leak connectWithContext exists to avoid: when ctx is canceled before a
+// slow, non-context-aware open finishes, the connection it eventually
+// produces must be closed instead of handed to nobody.
+func TestConnectWithContext_ClosesConnectionOpenedAfterCancel(t *testing.T) {
+	conn := &fakeConn{}
+	release := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	resultErrCh := make(chan error, 1)
+	go func() {
+		_, err := connectWithContext(ctx, func() (driver.Conn, error) {
+			<-release
+			return conn, nil
+		})
+		resultErrCh <- err
+	}()
+
+	cancel()
+
+	select {
+	case err := <-resultErrCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("connectWithContext returned error %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connectWithContext did not return promptly after cancel")
+	}
+
+	if conn.closed.Load() {
+		t.Fatal("conn was closed before open even returned it")
+	}
+
+	close(release)
+
+	// open's goroutine now delivers conn in the background; give it a moment
+	// to be closed rather than leaked.
+	deadline := time.After(time.Second)
+	for !conn.closed.Load() {
+		select {
+		case <-deadline:
+			t.Fatal("conn was never closed after the canceled Connect's open() returned it")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
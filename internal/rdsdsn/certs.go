@@ -0,0 +1,19 @@
+package rdsdsn
+
+import (
+	_ "embed"
+)
+
+//go:embed certs/global-bundle.pem
+var globalBundle []byte
+
+// Certificates is meant to be Amazon's RDS global CA bundle in PEM format
+// (which also covers RDS Proxy endpoints, since they validate against the
+// same chain), bundled here so callers don't have to discover and pass an
+// SSLCertificatePath themselves to get verify-full/verify-ca TLS working.
+// The embedded certs/global-bundle.pem is currently a self-signed placeholder
+// (see that file), not a real CA chain, so DefaultCertificatePath and
+// RegisterMySQLTLSConfig - the functions that actually wire Certificates into
+// TLS verification - refuse to run unless the rds_unsafe_placeholder_certs
+// build tag is set; see certs_placeholder.go.
+var Certificates = globalBundle
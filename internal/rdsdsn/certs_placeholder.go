@@ -0,0 +1,28 @@
+//go:build !rds_unsafe_placeholder_certs
+
+package rdsdsn
+
+import "github.com/pkg/errors"
+
+// errPlaceholderCerts is returned by DefaultCertificatePath and
+// RegisterMySQLTLSConfig in ordinary builds, since certs/global-bundle.pem is
+// a self-signed placeholder rather than Amazon's real RDS CA chain (see its
+// header comment). Wiring a fake root into verify-full/verify-ca TLS would
+// make callers believe they're getting real certificate validation when
+// they're not, so these functions fail loudly instead of doing that
+// silently. Build with -tags rds_unsafe_placeholder_certs (see
+// certs_placeholder_enabled.go) to use the placeholder anyway - e.g. for
+// local testing against a server presenting the same self-signed cert - or
+// supply your own SSLCertificatePath / mysql.RegisterTLSConfig for real TLS
+// verification.
+var errPlaceholderCerts = errors.New("bundled RDS CA chain (certs/global-bundle.pem) is a self-signed placeholder, not Amazon's real bundle; rebuild with -tags rds_unsafe_placeholder_certs to use it, or supply your own SSLCertificatePath / mysql.RegisterTLSConfig")
+
+// DefaultCertificatePath refuses to run; see errPlaceholderCerts.
+func DefaultCertificatePath() (string, error) {
+	return "", errPlaceholderCerts
+}
+
+// RegisterMySQLTLSConfig refuses to run; see errPlaceholderCerts.
+func RegisterMySQLTLSConfig(name string) error {
+	return errPlaceholderCerts
+}
@@ -0,0 +1,90 @@
+//go:build rds_unsafe_placeholder_certs
+
+package rdsdsn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+var (
+	certPool     *x509.CertPool
+	certPoolOnce sync.Once
+	certPoolErr  error
+)
+
+func loadCertPool() (*x509.CertPool, error) {
+	certPoolOnce.Do(func() {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(Certificates) {
+			certPoolErr = errors.New("no certificates found in the bundled RDS CA bundle")
+			return
+		}
+		certPool = pool
+	})
+
+	return certPool, certPoolErr
+}
+
+var (
+	certPath     string
+	certPathOnce sync.Once
+	certPathErr  error
+)
+
+// DefaultCertificatePath writes the bundled RDS CA chain to a temp file, once
+// per process, and returns its path. lib/pq's sslrootcert wants a file path
+// rather than inline PEM bytes, so this backs the postgres default when a
+// caller hasn't supplied their own SSLCertificatePath.
+func DefaultCertificatePath() (string, error) {
+	certPathOnce.Do(func() {
+		f, err := os.CreateTemp("", "rds-global-bundle-*.pem")
+		if err != nil {
+			certPathErr = errors.Wrap(err, "creating temp file for bundled RDS CA chain")
+			return
+		}
+		defer f.Close()
+
+		if _, err := f.Write(Certificates); err != nil {
+			certPathErr = errors.Wrap(err, "writing bundled RDS CA chain to temp file")
+			return
+		}
+
+		certPath = f.Name()
+	})
+
+	return certPath, certPathErr
+}
+
+var (
+	mysqlTLSMu   sync.Mutex
+	mysqlTLSDone = map[string]error{}
+)
+
+// RegisterMySQLTLSConfig registers name with the MySQL driver using the
+// bundled RDS CA chain as trusted roots, so a DSN can opt in via tls=<name>.
+// It's safe to call on every connection attempt; each distinct name is only
+// registered with the driver once per process.
+func RegisterMySQLTLSConfig(name string) error {
+	pool, err := loadCertPool()
+	if err != nil {
+		return err
+	}
+
+	mysqlTLSMu.Lock()
+	defer mysqlTLSMu.Unlock()
+
+	if registerErr, done := mysqlTLSDone[name]; done {
+		return registerErr
+	}
+
+	registerErr := mysql.RegisterTLSConfig(name, &tls.Config{RootCAs: pool})
+	mysqlTLSDone[name] = registerErr
+
+	return registerErr
+}
@@ -0,0 +1,27 @@
+//go:build rds_unsafe_placeholder_certs
+
+package rdsdsn
+
+import "testing"
+
+// TestRegisterMySQLTLSConfig_RegistersEachNameIndependently guards against a
+// single shared sync.Once silently no-opping every call after the first: a
+// second, distinct name must still reach mysql.RegisterTLSConfig rather than
+// returning the first call's cached result.
+func TestRegisterMySQLTLSConfig_RegistersEachNameIndependently(t *testing.T) {
+	if err := RegisterMySQLTLSConfig("rds-test-a"); err != nil {
+		t.Fatalf("RegisterMySQLTLSConfig(%q) returned error: %v", "rds-test-a", err)
+	}
+
+	if err := RegisterMySQLTLSConfig("rds-test-b"); err != nil {
+		t.Fatalf("RegisterMySQLTLSConfig(%q) returned error: %v", "rds-test-b", err)
+	}
+
+	if _, done := mysqlTLSDone["rds-test-a"]; !done {
+		t.Fatalf("rds-test-a was not recorded as registered")
+	}
+
+	if _, done := mysqlTLSDone["rds-test-b"]; !done {
+		t.Fatalf("rds-test-b was not recorded as registered")
+	}
+}
@@ -0,0 +1,80 @@
+package rdsdsn
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type fakeTokenBuilder struct {
+	token string
+}
+
+func (b fakeTokenBuilder) BuildAuthToken(context.Context, string, string, string) (string, error) {
+	return b.token, nil
+}
+
+// TestCreateRDSConnectionString_PostgresURLEncodesToken guards against the
+// keyword DSN form's blind spot: '=', '&' and '/' in an IAM auth token pass
+// through a "key=value" string untouched, but corrupt a "postgres://" URL
+// unless they're percent-encoded.
+func TestCreateRDSConnectionString_PostgresURLEncodesToken(t *testing.T) {
+	token := "a=b&c/d"
+	request := ConnectionRequest{
+		RDSType:            "postgres",
+		DBUser:             "iam_user",
+		Hostname:           "db.example.com",
+		Port:               5432,
+		DBName:             "app",
+		SSLCertificatePath: "/tmp/ca.pem",
+	}
+
+	dsn, err := CreateRDSConnectionString(context.Background(), fakeTokenBuilder{token: token}, request, PostgresDSNURL)
+	if err != nil {
+		t.Fatalf("CreateRDSConnectionString returned error: %v", err)
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("pgx-style DSN %q is not a valid URL: %v", dsn, err)
+	}
+
+	if got, _ := parsed.User.Password(); got != token {
+		t.Fatalf("token round-tripped as %q, want %q", got, token)
+	}
+
+	if parsed.User.Username() != request.DBUser {
+		t.Fatalf("user round-tripped as %q, want %q", parsed.User.Username(), request.DBUser)
+	}
+}
+
+// TestCreateRDSConnectionString_MySQLDisableMapsToFalse guards against a
+// mismatch between the postgres and mysql SSLMode vocabularies:
+// go-sql-driver/mysql only special-cases "true"/"false"/"skip-verify"/
+// "preferred" for tls=, so "disable" (the value that turns off TLS for
+// postgres' sslmode) would otherwise be looked up as an unregistered TLS
+// config name and fail to connect.
+func TestCreateRDSConnectionString_MySQLDisableMapsToFalse(t *testing.T) {
+	request := ConnectionRequest{
+		RDSType:  "mysql",
+		DBUser:   "iam_user",
+		Hostname: "db.example.com",
+		Port:     3306,
+		DBName:   "app",
+		SSLMode:  "disable",
+	}
+
+	dsn, err := CreateRDSConnectionString(context.Background(), fakeTokenBuilder{token: "tok"}, request, PostgresDSNKeyword)
+	if err != nil {
+		t.Fatalf("CreateRDSConnectionString returned error: %v", err)
+	}
+
+	if !strings.Contains(dsn, "tls=false") {
+		t.Fatalf("DSN %q does not contain tls=false", dsn)
+	}
+
+	if strings.Contains(dsn, "tls=disable") {
+		t.Fatalf("DSN %q still contains the unregistered tls=disable config name", dsn)
+	}
+}
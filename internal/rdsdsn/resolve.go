@@ -0,0 +1,162 @@
+package rdsdsn
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Resolver resolves a logical endpoint name - a DNS SRV query name, or an
+// Aurora/Multi-AZ cluster identifier - to the host and port a connection
+// should actually dial. GenericIAMDriver consults it, when configured,
+// before minting an IAM auth token, so the token is always generated for the
+// endpoint a connection will actually use.
+type Resolver interface {
+	Resolve(ctx context.Context, logicalName string) (host string, port int, err error)
+}
+
+// ReadOnlyResolver is implemented by Resolvers that can resolve to a reader
+// endpoint instead of the default writer/primary one, for
+// ConnectionRequest.ReadOnly.
+type ReadOnlyResolver interface {
+	Resolver
+	ResolveReadOnly(ctx context.Context, logicalName string) (host string, port int, err error)
+}
+
+// SRVResolver resolves a logical name to a concrete host:port via DNS SRV
+// records, picking a target the way SRV clients are expected to: lowest
+// priority first, then a weighted random choice among ties.
+type SRVResolver struct {
+	// Service and Proto are passed to net.Resolver.LookupSRV as-is. Leave
+	// both empty when logicalName is already a full SRV query name (e.g.
+	// "_postgresql._tcp.cluster.example.com"); set them (e.g. "postgresql",
+	// "tcp") to have LookupSRV build "_postgresql._tcp.<logicalName>" itself.
+	Service string
+	Proto   string
+}
+
+// Resolve implements Resolver.
+func (r SRVResolver) Resolve(ctx context.Context, logicalName string) (string, int, error) {
+	_, addrs, err := net.DefaultResolver.LookupSRV(ctx, r.Service, r.Proto, logicalName)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "looking up SRV records")
+	}
+
+	if len(addrs) == 0 {
+		return "", 0, errors.Errorf("no SRV records found for %v", logicalName)
+	}
+
+	target := pickSRVTarget(addrs)
+
+	return strings.TrimSuffix(target.Target, "."), int(target.Port), nil
+}
+
+// pickSRVTarget selects among the lowest-priority records in addrs, weighted
+// per RFC 2782: higher Weight means proportionally more likely to be picked.
+func pickSRVTarget(addrs []*net.SRV) *net.SRV {
+	lowestPriority := addrs[0].Priority
+	for _, a := range addrs {
+		if a.Priority < lowestPriority {
+			lowestPriority = a.Priority
+		}
+	}
+
+	var candidates []*net.SRV
+	totalWeight := 0
+	for _, a := range addrs {
+		if a.Priority == lowestPriority {
+			candidates = append(candidates, a)
+			totalWeight += int(a.Weight)
+		}
+	}
+
+	if totalWeight == 0 {
+		return candidates[rand.Intn(len(candidates))]
+	}
+
+	pick := rand.Intn(totalWeight)
+	for _, c := range candidates {
+		if pick < int(c.Weight) {
+			return c
+		}
+		pick -= int(c.Weight)
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+// resolvedEndpoint is a cached (host, port) pair together with when it was resolved.
+type resolvedEndpoint struct {
+	host       string
+	port       int
+	resolvedAt time.Time
+}
+
+// inflightResolve lets concurrent Resolve calls for the same key wait on a
+// single in-flight lookup instead of each performing their own.
+type inflightResolve struct {
+	wg   sync.WaitGroup
+	host string
+	port int
+	err  error
+}
+
+// ResolutionCache caches Resolver results keyed by an arbitrary caller-chosen
+// string (typically the logical name being resolved), treating entries older
+// than ttl as a miss. Callers that observe a resolved endpoint failing can
+// call Invalidate so the very next Resolve call re-resolves, instead of
+// handing out the same bad target until the TTL lapses.
+type ResolutionCache struct {
+	ttl      time.Duration
+	entries  sync.Map // map[string]*resolvedEndpoint
+	inflight sync.Map // map[string]*inflightResolve
+}
+
+// NewResolutionCache creates a ResolutionCache whose entries are considered stale after ttl.
+func NewResolutionCache(ttl time.Duration) *ResolutionCache {
+	return &ResolutionCache{ttl: ttl}
+}
+
+// Resolve returns the cached (host, port) for key if it's still fresh,
+// otherwise calls resolve - single-flighted across concurrent callers for
+// the same key - and caches the result.
+func (c *ResolutionCache) Resolve(key string, resolve func() (string, int, error)) (string, int, error) {
+	if v, ok := c.entries.Load(key); ok {
+		entry := v.(*resolvedEndpoint)
+		if time.Since(entry.resolvedAt) < c.ttl {
+			return entry.host, entry.port, nil
+		}
+	}
+
+	call := new(inflightResolve)
+	call.wg.Add(1)
+
+	actual, loaded := c.inflight.LoadOrStore(key, call)
+	if loaded {
+		call = actual.(*inflightResolve)
+		call.wg.Wait()
+		return call.host, call.port, call.err
+	}
+
+	defer func() {
+		c.inflight.Delete(key)
+		call.wg.Done()
+	}()
+
+	call.host, call.port, call.err = resolve()
+	if call.err == nil {
+		c.entries.Store(key, &resolvedEndpoint{host: call.host, port: call.port, resolvedAt: time.Now()})
+	}
+
+	return call.host, call.port, call.err
+}
+
+// Invalidate discards any cached resolution for key.
+func (c *ResolutionCache) Invalidate(key string) {
+	c.entries.Delete(key)
+}
@@ -0,0 +1,68 @@
+package rdsdsn
+
+import (
+	"net"
+	"testing"
+)
+
+// TestPickSRVTarget_PrefersLowestPriority guards the first step of RFC 2782
+// selection: a record at a higher (less-preferred) priority must never be
+// picked while a lower-priority record exists, regardless of weight.
+func TestPickSRVTarget_PrefersLowestPriority(t *testing.T) {
+	preferred := &net.SRV{Target: "writer.example.com.", Port: 5432, Priority: 0, Weight: 1}
+	addrs := []*net.SRV{
+		{Target: "backup.example.com.", Port: 5432, Priority: 10, Weight: 100},
+		preferred,
+	}
+
+	for i := 0; i < 50; i++ {
+		if got := pickSRVTarget(addrs); got != preferred {
+			t.Fatalf("pickSRVTarget() = %+v, want the lowest-priority record %+v", got, preferred)
+		}
+	}
+}
+
+// TestPickSRVTarget_ZeroWeightFallsBackToUniformChoice guards the
+// totalWeight == 0 branch: when every candidate at the lowest priority has
+// weight 0, pickSRVTarget must still return one of them instead of panicking
+// on rand.Intn(0) or returning nil.
+func TestPickSRVTarget_ZeroWeightFallsBackToUniformChoice(t *testing.T) {
+	candidates := []*net.SRV{
+		{Target: "a.example.com.", Port: 5432, Priority: 0, Weight: 0},
+		{Target: "b.example.com.", Port: 5432, Priority: 0, Weight: 0},
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		got := pickSRVTarget(candidates)
+		if got == nil {
+			t.Fatalf("pickSRVTarget() = nil, want a candidate")
+		}
+		seen[got.Target] = true
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("pickSRVTarget() only ever returned %v across 50 calls, want both zero-weight candidates to show up", seen)
+	}
+}
+
+// TestPickSRVTarget_WeightedSelectionRespectsProportions guards the weighted
+// branch: with a 99:1 weight split, the heavier candidate must dominate the
+// selection rather than being picked uniformly at random.
+func TestPickSRVTarget_WeightedSelectionRespectsProportions(t *testing.T) {
+	heavy := &net.SRV{Target: "heavy.example.com.", Port: 5432, Priority: 0, Weight: 99}
+	light := &net.SRV{Target: "light.example.com.", Port: 5432, Priority: 0, Weight: 1}
+	candidates := []*net.SRV{heavy, light}
+
+	heavyPicks := 0
+	const trials = 500
+	for i := 0; i < trials; i++ {
+		if pickSRVTarget(candidates) == heavy {
+			heavyPicks++
+		}
+	}
+
+	if heavyPicks < trials/2 {
+		t.Fatalf("heavy candidate (weight 99) was picked %d/%d times, want a clear majority", heavyPicks, trials)
+	}
+}
@@ -0,0 +1,81 @@
+package rdsdsn
+
+import (
+	"sync"
+	"time"
+)
+
+// cachedEntry is a single IAM-authenticated DSN together with when it was
+// minted, so TokenCache can tell a fresh entry from a stale one.
+type cachedEntry struct {
+	dsn         string
+	generatedAt time.Time
+}
+
+// inflightCall lets concurrent Refresh calls for the same key wait on a
+// single in-flight token generation instead of each generating their own.
+type inflightCall struct {
+	wg  sync.WaitGroup
+	dsn string
+	err error
+}
+
+// TokenCache caches IAM-authenticated DSNs keyed by an arbitrary caller-chosen
+// string (e.g. the input DSN, or a request fingerprint), treating entries
+// older than ttl as a miss. RDS IAM tokens expire 15 minutes after they're
+// minted, so ttl should stay comfortably under that.
+type TokenCache struct {
+	ttl      time.Duration
+	entries  sync.Map // map[string]*cachedEntry
+	inflight sync.Map // map[string]*inflightCall
+}
+
+// NewTokenCache creates a TokenCache whose entries are considered stale after ttl.
+func NewTokenCache(ttl time.Duration) *TokenCache {
+	return &TokenCache{ttl: ttl}
+}
+
+// Get returns the cached DSN for key, if one exists and is younger than the
+// cache's TTL.
+func (c *TokenCache) Get(key string) (string, bool) {
+	v, ok := c.entries.Load(key)
+	if !ok {
+		return "", false
+	}
+
+	entry := v.(*cachedEntry)
+	if time.Since(entry.generatedAt) >= c.ttl {
+		return "", false
+	}
+
+	return entry.dsn, true
+}
+
+// Refresh regenerates the DSN for key via build and caches the result.
+// Concurrent Refresh calls for the same key single-flight: only the first
+// caller invokes build, and the rest wait for and share its result, so a
+// burst of callers hitting a stale or missing entry doesn't mint a token per
+// caller.
+func (c *TokenCache) Refresh(key string, build func() (string, error)) (string, error) {
+	call := new(inflightCall)
+	call.wg.Add(1)
+
+	actual, loaded := c.inflight.LoadOrStore(key, call)
+	if loaded {
+		call = actual.(*inflightCall)
+		call.wg.Wait()
+		return call.dsn, call.err
+	}
+
+	defer func() {
+		c.inflight.Delete(key)
+		call.wg.Done()
+	}()
+
+	call.dsn, call.err = build()
+	if call.err == nil {
+		c.entries.Store(key, &cachedEntry{dsn: call.dsn, generatedAt: time.Now()})
+	}
+
+	return call.dsn, call.err
+}
@@ -0,0 +1,79 @@
+package rdsdsn
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestTokenCache_GetTreatsStaleEntryAsMiss guards the TTL check in Get: an
+// entry older than the cache's ttl must be reported as a miss so callers fall
+// through to Refresh, rather than handing out an IAM auth token that's at
+// risk of having expired server-side.
+func TestTokenCache_GetTreatsStaleEntryAsMiss(t *testing.T) {
+	c := NewTokenCache(10 * time.Millisecond)
+
+	if _, err := c.Refresh("key", func() (string, error) { return "dsn-1", nil }); err != nil {
+		t.Fatalf("Refresh returned error: %v", err)
+	}
+
+	if dsn, ok := c.Get("key"); !ok || dsn != "dsn-1" {
+		t.Fatalf("Get() = (%q, %v), want (\"dsn-1\", true) immediately after Refresh", dsn, ok)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if dsn, ok := c.Get("key"); ok {
+		t.Fatalf("Get() = (%q, %v), want a miss once the entry is older than the TTL", dsn, ok)
+	}
+}
+
+// TestTokenCache_RefreshSingleFlightsConcurrentCallers guards the single-flight
+// behavior: a burst of concurrent Refresh calls for the same key that hit a
+// stale or missing entry must only invoke build once, with the rest waiting
+// for and sharing its result, instead of each minting its own IAM auth token.
+func TestTokenCache_RefreshSingleFlightsConcurrentCallers(t *testing.T) {
+	c := NewTokenCache(time.Minute)
+
+	var calls int32
+	release := make(chan struct{})
+	build := func() (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "dsn", nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]string, callers)
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		i := i
+		go func() {
+			defer wg.Done()
+			dsn, err := c.Refresh("key", build)
+			if err != nil {
+				t.Errorf("Refresh returned error: %v", err)
+			}
+			results[i] = dsn
+		}()
+	}
+
+	// Give every goroutine a chance to reach Refresh and block in build
+	// before letting it return, so LoadOrStore's single-flighting is
+	// actually exercised rather than the calls running one after another.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("build was called %d times, want exactly 1", got)
+	}
+
+	for i, dsn := range results {
+		if dsn != "dsn" {
+			t.Fatalf("results[%d] = %q, want %q", i, dsn, "dsn")
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package rdsiamv2 provides the same IAM-authenticated database/sql drivers as
+// the root go_rds_iam package, built on github.com/aws/aws-sdk-go-v2 instead
+// of the (now maintenance-mode) v1 SDK.
+package rdsiamv2
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/RohanPoojary/go-rds-iam/internal/rdsdsn"
+)
+
+// ConnectionRDSType identifies which RDS engine a ConnectionRequest targets.
+type ConnectionRDSType = rdsdsn.ConnectionRDSType
+
+// ConnectionRequest represents a request to connect to an RDS instance.
+type ConnectionRequest = rdsdsn.ConnectionRequest
+
+// Certificates is meant to be Amazon's RDS global CA bundle (which also
+// covers RDS Proxy endpoints) in PEM format. It's used automatically when a
+// ConnectionRequest leaves SSLCertificatePath empty and sets SSLMode to a
+// value other than "disable" (postgres) or to "rds" (mysql); see
+// rdsdsn.Certificates for the current placeholder caveat.
+var Certificates = rdsdsn.Certificates
+
+// defaultTokenTTL is how long a cached IAM auth token is considered fresh.
+// RDS IAM tokens expire 15 minutes after they're minted; refreshing a few
+// minutes early avoids handing out a token that's about to be rejected.
+const defaultTokenTTL = 10 * time.Minute
+
+// Option configures a GenericIAMDriver constructed by RegisterAWSRDSIAMDriversV2.
+type Option func(*GenericIAMDriver)
+
+// WithTokenTTL overrides how long a cached IAM auth token is considered
+// fresh. It should stay comfortably under RDS's 15-minute token expiry.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(d *GenericIAMDriver) {
+		d.tokenTTL = ttl
+	}
+}
+
+// configTokenBuilder generates IAM auth tokens via aws-sdk-go-v2, sharing
+// DSN building logic with the v1 backend in the root go_rds_iam package.
+type configTokenBuilder struct {
+	cfg aws.Config
+}
+
+func (b *configTokenBuilder) BuildAuthToken(ctx context.Context, endpoint, region, user string) (string, error) {
+	return auth.BuildAuthToken(ctx, endpoint, region, user, b.cfg.Credentials)
+}
+
+func createRDSConnectionString(ctx context.Context, cfg aws.Config, request ConnectionRequest) (string, error) {
+	return rdsdsn.CreateRDSConnectionString(ctx, &configTokenBuilder{cfg: cfg}, request, rdsdsn.PostgresDSNKeyword)
+}
+
+// GenericIAMDriver is a database driver that uses IAM authentication to connect to RDS instances.
+type GenericIAMDriver struct {
+	awsConfig aws.Config
+	rdsType   ConnectionRDSType
+	tokenTTL  time.Duration
+	tokens    *rdsdsn.TokenCache
+}
+
+// Open opens a new database connection using IAM authentication.
+func (d *GenericIAMDriver) Open(dsn string) (driver.Conn, error) {
+	request, err := rdsdsn.ParseConnectionRequestFromDSN(d.rdsType, dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error in Parsing DSN: "+dsn)
+	}
+
+	request.Region = d.awsConfig.Region
+	request.RDSType = ConnectionRDSType(d.rdsType)
+
+	return d.openCached(dsn, request)
+}
+
+// openCached opens a connection for request, reusing the IAM DSN cached under
+// cacheKey while it's still within the driver's token TTL, and only
+// regenerating it when the cache misses, the entry is stale, or the cached
+// connection fails to open.
+func (d *GenericIAMDriver) openCached(cacheKey string, request ConnectionRequest) (driver.Conn, error) {
+	if iamDSN, ok := d.tokens.Get(cacheKey); ok {
+		if conn, err := d.open(iamDSN); err == nil {
+			log.Println("Using cached IAM DSN for connection: ", cacheKey)
+			return conn, nil
+		}
+	}
+
+	iamDSN, err := d.tokens.Refresh(cacheKey, func() (string, error) {
+		return createRDSConnectionString(context.Background(), d.awsConfig, request)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "createRDSConnectionString")
+	}
+
+	log.Println("Created New DSN for connection: ", cacheKey)
+
+	return d.open(iamDSN)
+}
+
+func (d *GenericIAMDriver) open(dsn string) (driver.Conn, error) {
+	if d.rdsType == "postgres" {
+		return pq.Driver{}.Open(dsn)
+	} else {
+		return mysql.MySQLDriver{}.Open(dsn)
+	}
+}
+
+// RegisterAWSRDSIAMDriversV2 registers a new database driver for the given RDS type
+// (e.g., "postgres" or "mysql") that uses IAM authentication, via aws-sdk-go-v2,
+// to connect to RDS instances.
+func RegisterAWSRDSIAMDriversV2(cfg aws.Config, rdsType ConnectionRDSType, opts ...Option) string {
+	driverName := "aws_" + string(rdsType) + "_iam_v2"
+
+	d := &GenericIAMDriver{
+		awsConfig: cfg,
+		rdsType:   rdsType,
+		tokenTTL:  defaultTokenTTL,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d.tokens = rdsdsn.NewTokenCache(d.tokenTTL)
+
+	sql.Register(driverName, d)
+
+	return driverName
+}
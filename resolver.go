@@ -0,0 +1,82 @@
+package go_rds_iam
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/pkg/errors"
+
+	"github.com/RohanPoojary/go-rds-iam/internal/rdsdsn"
+)
+
+// SRVResolver resolves a logical name to a concrete host:port via DNS SRV
+// records. See rdsdsn.SRVResolver for field documentation.
+type SRVResolver = rdsdsn.SRVResolver
+
+// ClusterResolver resolves an Aurora/Multi-AZ DB cluster identifier to its
+// current writer endpoint, or a randomly chosen reader endpoint when the
+// ConnectionRequest has ReadOnly set, via the RDS API. Use it with
+// WithResolver so connections follow failovers instead of targeting whatever
+// endpoint happened to be current when the driver was constructed.
+type ClusterResolver struct {
+	rds *rds.RDS
+}
+
+// NewClusterResolver builds a ClusterResolver backed by the RDS API client
+// for sess.
+func NewClusterResolver(sess *session.Session) *ClusterResolver {
+	return &ClusterResolver{rds: rds.New(sess)}
+}
+
+// Resolve implements rdsdsn.Resolver. logicalName is the DBClusterIdentifier.
+func (r *ClusterResolver) Resolve(ctx context.Context, logicalName string) (string, int, error) {
+	out, err := r.rds.DescribeDBClustersWithContext(ctx, &rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(logicalName),
+	})
+	if err != nil {
+		return "", 0, errors.Wrap(err, "DescribeDBClusters")
+	}
+
+	if len(out.DBClusters) == 0 {
+		return "", 0, errors.Errorf("no DB cluster found for %v", logicalName)
+	}
+
+	cluster := out.DBClusters[0]
+	port := int(aws.Int64Value(cluster.Port))
+
+	return aws.StringValue(cluster.Endpoint), port, nil
+}
+
+// ResolveReadOnly implements rdsdsn.ReadOnlyResolver, resolving to a randomly
+// selected reader endpoint of the cluster instead of the writer. It's
+// consulted instead of Resolve when a ConnectionRequest has ReadOnly set.
+func (r *ClusterResolver) ResolveReadOnly(ctx context.Context, logicalName string) (string, int, error) {
+	writerHost, port, err := r.Resolve(ctx, logicalName)
+	if err != nil {
+		return "", 0, err
+	}
+
+	out, err := r.rds.DescribeDBClusterEndpointsWithContext(ctx, &rds.DescribeDBClusterEndpointsInput{
+		DBClusterIdentifier: aws.String(logicalName),
+		Filters: []*rds.Filter{{
+			Name:   aws.String("db-cluster-endpoint-type"),
+			Values: aws.StringSlice([]string{"reader"}),
+		}},
+	})
+	if err != nil {
+		return "", 0, errors.Wrap(err, "DescribeDBClusterEndpoints")
+	}
+
+	if len(out.DBClusterEndpoints) == 0 {
+		// No dedicated reader endpoint (e.g. a single-instance cluster) -
+		// fall back to the writer rather than failing the connection.
+		return writerHost, port, nil
+	}
+
+	picked := out.DBClusterEndpoints[rand.Intn(len(out.DBClusterEndpoints))]
+
+	return aws.StringValue(picked.Endpoint), port, nil
+}